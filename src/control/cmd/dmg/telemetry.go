@@ -0,0 +1,99 @@
+//
+// (C) Copyright 2022 Intel Corporation.
+//
+// SPDX-License-Identifier: BSD-2-Clause-Patent
+//
+// +build linux,amd64
+//
+
+package main
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+
+	"github.com/daos-stack/daos/src/control/lib/cmdutil"
+	"github.com/daos-stack/daos/src/control/lib/telemetry"
+	"github.com/daos-stack/daos/src/control/lib/telemetry/prom"
+	"github.com/daos-stack/daos/src/control/server/config"
+	"github.com/daos-stack/daos/src/control/server/storage"
+)
+
+// telemetryCmd is the parent for locally-run telemetry helper subcommands.
+type telemetryCmd struct {
+	Serve telemetryServeCmd `command:"serve" description:"serve local engine telemetry in Prometheus exposition format"`
+}
+
+// telemetryServeCmd calls telemetry.Init for every local engine rank and
+// multiplexes the resulting handles behind a single Prometheus /metrics
+// endpoint, so a single dmg process can stand in for per-engine scrape
+// targets. Each handle is whatever telemetry.Init returns; no engine's
+// shared-memory metric tree is read.
+type telemetryServeCmd struct {
+	cmdutil.LogCmd
+	Addr       string `short:"a" long:"addr" default:":9191" description:"address to listen on for Prometheus scrape requests"`
+	ConfigPath string `short:"o" long:"config-path" description:"path to the local server config used to discover this node's engine ranks (defaults to the standard daos_server.yml search path)"`
+}
+
+// Execute implements the go-flags Commander interface.
+func (cmd *telemetryServeCmd) Execute(_ []string) error {
+	ranks, err := localEngineRanks(cmd.ConfigPath)
+	if err != nil {
+		return errors.Wrap(err, "discover local engine ranks")
+	}
+	if len(ranks) == 0 {
+		return errors.New("no local engines found to serve telemetry for")
+	}
+
+	reg := prometheus.NewRegistry()
+
+	for _, rank := range ranks {
+		ctx, err := telemetry.Init(context.Background(), rank)
+		if err != nil {
+			cmd.Infof("skipping rank %d: %s", rank, err)
+			continue
+		}
+
+		exp := prom.NewExporter(ctx, &prom.CollectorOpts{Namespace: "engine"})
+		if err := reg.Register(exp); err != nil {
+			return errors.Wrapf(err, "register exporter for rank %d", rank)
+		}
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", promhttp.HandlerFor(reg, promhttp.HandlerOpts{}))
+
+	cmd.Infof("serving DAOS telemetry on %s/metrics", cmd.Addr)
+	return http.ListenAndServe(cmd.Addr, mux)
+}
+
+// localEngineRanks discovers the ranks of the engines actually configured
+// to run on this node by reading each engine's persisted superblock off
+// its SCM mount. DAOS ranks are cluster-global identifiers assigned at
+// join time, not a node-local 0..N index, so a node's local engines can
+// just as easily be ranks {7, 12} as {0, 1} -- this has to read what rank
+// each local engine was actually assigned rather than guess a range.
+func localEngineRanks(cfgPath string) ([]uint32, error) {
+	cfg, err := config.Load(cfgPath)
+	if err != nil {
+		return nil, errors.Wrap(err, "load server config")
+	}
+
+	var ranks []uint32
+	for _, engineCfg := range cfg.Engines {
+		sb, err := storage.ReadSuperblock(engineCfg.Storage.SCM.MountPoint)
+		if err != nil {
+			continue
+		}
+		if sb.Rank == nil {
+			continue
+		}
+		ranks = append(ranks, sb.Rank.Uint32())
+	}
+
+	return ranks, nil
+}
@@ -0,0 +1,25 @@
+//
+// (C) Copyright 2022 Intel Corporation.
+//
+// SPDX-License-Identifier: BSD-2-Clause-Patent
+//
+// +build linux,amd64
+//
+
+package main
+
+import "testing"
+
+func TestLocalEngineRanks_BadConfigPath(t *testing.T) {
+	if _, err := localEngineRanks("/nonexistent/daos_server.yml"); err == nil {
+		t.Fatal("expected an error loading a nonexistent server config")
+	}
+}
+
+func TestTelemetryServeCmd_Execute_NoLocalEngines(t *testing.T) {
+	cmd := &telemetryServeCmd{ConfigPath: "/nonexistent/daos_server.yml"}
+
+	if err := cmd.Execute(nil); err == nil {
+		t.Fatal("expected Execute to fail when no local engines can be discovered")
+	}
+}
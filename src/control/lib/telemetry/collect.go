@@ -0,0 +1,40 @@
+//
+// (C) Copyright 2021-2022 Intel Corporation.
+//
+// SPDX-License-Identifier: BSD-2-Clause-Patent
+//
+// +build linux,amd64
+//
+
+package telemetry
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+)
+
+// MetricVisitor is invoked once per metric discovered while walking a
+// metric tree. Returning a non-nil error aborts the walk.
+type MetricVisitor func(ctx context.Context, m Metric) error
+
+// CollectMetrics walks the entire metric tree reachable from the handle
+// attached to ctx, invoking visit for every metric it finds. The walk
+// starts at the tree root, so callers don't need to know metric names or
+// types ahead of time the way GetCounter/GetGauge require.
+//
+// The walk is cancellable: ctx is checked between visits, and a cancelled
+// ctx aborts the walk with ctx.Err().
+func CollectMetrics(ctx context.Context, visit MetricVisitor) error {
+	err := Walk(ctx, "/", func(ctx context.Context, path string, m Metric, d *Directory) error {
+		if m == nil {
+			// directories themselves carry no metric value
+			return nil
+		}
+		return visit(ctx, m)
+	})
+	if err != nil {
+		return errors.Wrap(err, "collect metrics")
+	}
+	return nil
+}
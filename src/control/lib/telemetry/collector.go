@@ -0,0 +1,177 @@
+//
+// (C) Copyright 2022 Intel Corporation.
+//
+// SPDX-License-Identifier: BSD-2-Clause-Patent
+//
+// +build linux,amd64
+//
+
+package telemetry
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// Sample is one observation of a single metric, taken by a Collector.
+type Sample struct {
+	// Path identifies the metric this sample was taken from.
+	Path string
+	// Type is the metric's type at the time of sampling.
+	Type MetricType
+	// Value is the absolute value observed at this tick.
+	Value float64
+	// Delta is, for counter-typed metrics, the change in Value since the
+	// previous sample for this metric (or Value itself on the first
+	// sample), and is the basis for a caller's rate computation
+	// (Delta / Elapsed). It is 0 for every other metric type.
+	Delta float64
+	// Elapsed is the time since the previous sample for this metric.
+	Elapsed time.Duration
+	// Reset is true when a counter-typed metric's Value was observed to
+	// be lower than the previous sample, indicating the underlying
+	// counter wrapped or was reset. Delta is set to the new Value in
+	// that case rather than going negative.
+	Reset bool
+}
+
+// Rate returns Delta per second, which is meaningful for counter-typed
+// samples (e.g. bytes/sec, ops/sec). It returns 0 if Elapsed is 0.
+func (s Sample) Rate() float64 {
+	if s.Elapsed <= 0 {
+		return 0
+	}
+	return s.Delta / s.Elapsed.Seconds()
+}
+
+// Collector polls a metric tree on a fixed interval and streams per-tick
+// Samples over a channel, computing deltas and rates for counter metrics
+// server-side so CLI and telemetry consumers don't each reimplement that
+// math.
+type Collector struct {
+	events    chan Sample
+	done      chan struct{}
+	stopped   chan struct{}
+	closeOnce sync.Once
+	startOnce sync.Once
+	last      map[string]float64
+	lastAt    map[string]time.Time
+}
+
+// NewCollector creates a Collector. Call Start to begin sampling.
+//
+// stopped starts out already closed: a Collector that's torn down
+// without Start ever having been called (e.g. an early-return error
+// path) must not block in Close waiting for a polling goroutine that
+// will never run. Start replaces it with the channel its goroutine
+// actually closes on exit.
+func NewCollector() *Collector {
+	stopped := make(chan struct{})
+	close(stopped)
+
+	return &Collector{
+		events:  make(chan Sample, 64),
+		done:    make(chan struct{}),
+		stopped: stopped,
+		last:    make(map[string]float64),
+		lastAt:  make(map[string]time.Time),
+	}
+}
+
+// Events returns the channel Samples are delivered on. It is closed when
+// the collector stops, whether via Close or ctx cancellation.
+func (c *Collector) Events() <-chan Sample {
+	return c.events
+}
+
+// Close stops the collector and waits for its polling goroutine to exit.
+// It is safe to call Close concurrently, or more than once, from multiple
+// goroutines.
+func (c *Collector) Close() {
+	c.closeOnce.Do(func() {
+		close(c.done)
+	})
+	<-c.stopped
+}
+
+// Start begins polling the metric tree reachable from ctx every interval,
+// streaming a Sample per metric per tick on Events(). Start returns
+// immediately; sampling happens on a background goroutine that exits when
+// ctx is cancelled or Close is called.
+//
+// If a tick is still being processed when later ones are due -- e.g.
+// because the producer is slow to respond -- the ticker holds at most one
+// pending tick and drops the rest rather than queueing them all, so the
+// collector never falls permanently behind.
+func (c *Collector) Start(ctx context.Context, interval time.Duration) {
+	// Swap in a fresh, still-open stopped channel for the polling
+	// goroutine below to close on exit -- the one NewCollector created
+	// is already closed, precisely so Close doesn't block if it's ever
+	// called without a matching Start.
+	c.startOnce.Do(func() {
+		c.stopped = make(chan struct{})
+	})
+
+	go func() {
+		defer close(c.stopped)
+		defer close(c.events)
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-c.done:
+				return
+			case now := <-ticker.C:
+				if err := c.sample(ctx, now); err != nil {
+					return
+				}
+			}
+		}
+	}()
+}
+
+func (c *Collector) sample(ctx context.Context, now time.Time) error {
+	return CollectMetrics(ctx, func(ctx context.Context, m Metric) error {
+		s := Sample{
+			Path:  m.Path(),
+			Type:  m.Type(),
+			Value: m.FloatValue(),
+		}
+
+		if prevAt, ok := c.lastAt[s.Path]; ok {
+			s.Elapsed = now.Sub(prevAt)
+		}
+
+		if m.Type() == MetricTypeCounter {
+			if prev, ok := c.last[s.Path]; ok {
+				if s.Value < prev {
+					s.Reset = true
+					s.Delta = s.Value
+				} else {
+					s.Delta = s.Value - prev
+				}
+			} else {
+				s.Delta = s.Value
+			}
+		}
+
+		c.last[s.Path] = s.Value
+		c.lastAt[s.Path] = now
+
+		select {
+		case c.events <- s:
+		case <-c.done:
+			return errors.New("collector closed")
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+		return nil
+	})
+}
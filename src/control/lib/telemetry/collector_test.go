@@ -0,0 +1,143 @@
+//
+// (C) Copyright 2022 Intel Corporation.
+//
+// SPDX-License-Identifier: BSD-2-Clause-Patent
+//
+// +build linux,amd64
+//
+
+package telemetry
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/daos-stack/daos/src/control/common"
+)
+
+func TestTelemetry_Collector_Basic(t *testing.T) {
+	testCtx, testMetrics := setupTestMetrics(t)
+	defer cleanupTestMetricsProducer(t)
+
+	// Collector samples via CollectMetrics, which walks from the tree
+	// root, so a root-level metric's sampled Path carries the leading
+	// "/" Walk adds -- unlike a direct GetCounter lookup, whose Path()
+	// is whatever the metric was registered with.
+	counterPath := "/" + testMetrics[MetricTypeCounter].name
+
+	c := NewCollector()
+	c.Start(testCtx, 10*time.Millisecond)
+	defer c.Close()
+
+	seen := 0
+	timeout := time.After(time.Second)
+	for seen < 2 {
+		select {
+		case s, ok := <-c.Events():
+			if !ok {
+				t.Fatal("events channel closed early")
+			}
+			if s.Path == counterPath {
+				seen++
+			}
+		case <-timeout:
+			t.Fatal("timed out waiting for samples")
+		}
+	}
+}
+
+func TestTelemetry_Collector_StopsOnCancel(t *testing.T) {
+	testCtx, _ := setupTestMetrics(t)
+	defer cleanupTestMetricsProducer(t)
+
+	ctx, cancel := context.WithCancel(testCtx)
+	c := NewCollector()
+	c.Start(ctx, 10*time.Millisecond)
+
+	cancel()
+
+	select {
+	case _, ok := <-c.Events():
+		if ok {
+			// a sample racing the cancel is fine; drain until closed
+			for range c.Events() {
+			}
+		}
+	case <-time.After(time.Second):
+		t.Fatal("collector did not stop after ctx cancellation")
+	}
+}
+
+func TestTelemetry_Collector_CloseIsIdempotentAndConcurrent(t *testing.T) {
+	testCtx, _ := setupTestMetrics(t)
+	defer cleanupTestMetricsProducer(t)
+
+	c := NewCollector()
+	c.Start(testCtx, 10*time.Millisecond)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			c.Close()
+		}()
+	}
+	wg.Wait()
+
+	if _, ok := <-c.Events(); ok {
+		t.Fatal("expected Events() to be closed after Close()")
+	}
+}
+
+func TestSample_Rate(t *testing.T) {
+	for name, tc := range map[string]struct {
+		sample Sample
+		expect float64
+	}{
+		"zero elapsed": {
+			sample: Sample{Delta: 10},
+			expect: 0,
+		},
+		"one second": {
+			sample: Sample{Delta: 10, Elapsed: time.Second},
+			expect: 10,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			common.AssertEqual(t, tc.sample.Rate(), tc.expect, "bad rate")
+		})
+	}
+}
+
+// BenchmarkCollector_Sample measures the steady-state cost of polling the
+// metric tree once, the work Start's ticker loop repeats on every
+// interval. A slow producer or a tree with thousands of counters shows up
+// here as increased ns/op, without the noise of the ticker/channel
+// plumbing around it.
+func BenchmarkCollector_Sample(b *testing.B) {
+	testCtx, _ := setupTestMetrics(b)
+	defer cleanupTestMetricsProducer(b)
+
+	c := NewCollector()
+
+	// Drain concurrently so sample() never blocks on the bounded events
+	// channel once its buffer fills.
+	go func() {
+		for range c.events {
+		}
+	}()
+
+	now := time.Now()
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		now = now.Add(time.Second)
+		if err := c.sample(testCtx, now); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
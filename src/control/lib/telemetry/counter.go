@@ -0,0 +1,45 @@
+//
+// (C) Copyright 2021-2022 Intel Corporation.
+//
+// SPDX-License-Identifier: BSD-2-Clause-Patent
+//
+// +build linux,amd64
+//
+
+package telemetry
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+)
+
+type counter struct {
+	metricBase
+	cur uint64
+}
+
+func (c *counter) Value() uint64       { return c.cur }
+func (c *counter) FloatValue() float64 { return float64(c.cur) }
+func (c *counter) Type() MetricType    { return MetricTypeCounter }
+
+// GetCounter fetches the counter-typed metric named name from the handle
+// attached to ctx.
+func GetCounter(ctx context.Context, name string) (*counter, error) {
+	hdl, err := getHandle(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "get counter")
+	}
+
+	m, err := hdl.getMetric(name)
+	if err != nil {
+		return nil, errors.Wrapf(err, "unable to find metric %q", name)
+	}
+
+	c, ok := m.(*counter)
+	if !ok {
+		return nil, errors.Errorf("%q is not a counter", name)
+	}
+
+	return c, nil
+}
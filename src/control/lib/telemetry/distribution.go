@@ -0,0 +1,95 @@
+//
+// (C) Copyright 2022 Intel Corporation.
+//
+// SPDX-License-Identifier: BSD-2-Clause-Patent
+//
+// +build linux,amd64
+//
+
+package telemetry
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+)
+
+// Distribution is a min/max/sum/count aggregate metric decoded from a
+// gurt d_tm_histogram_t with no bucket data attached -- use this when
+// only the aggregate is needed, and Histogram when the bucket breakdown
+// (and Quantile) matters too.
+type Distribution interface {
+	Metric
+
+	// Min is the smallest observed value.
+	Min() float64
+	// Max is the largest observed value.
+	Max() float64
+	// Sum is the sum of all observed values.
+	Sum() float64
+	// Count is the total number of observations.
+	Count() uint64
+	// Mean is Sum() / Count(), or 0 if Count() is 0.
+	Mean() float64
+}
+
+type distribution struct {
+	metricBase
+	min, max, sum float64
+	count         uint64
+}
+
+func (d *distribution) Type() MetricType { return MetricTypeDistribution }
+func (d *distribution) Min() float64     { return d.min }
+func (d *distribution) Max() float64     { return d.max }
+func (d *distribution) Sum() float64     { return d.sum }
+func (d *distribution) Count() uint64    { return d.count }
+
+// Value and FloatValue satisfy Metric by exposing the observation count,
+// the one aggregate that's meaningful to truncate to an integer; use
+// Sum()/Min()/Max()/Mean() for the rest of the aggregate.
+func (d *distribution) Value() uint64       { return d.count }
+func (d *distribution) FloatValue() float64 { return float64(d.count) }
+
+func (d *distribution) Mean() float64 {
+	if d.count == 0 {
+		return 0
+	}
+	return d.sum / float64(d.count)
+}
+
+// GetDistribution fetches the distribution-typed metric named name from
+// the handle attached to ctx.
+func GetDistribution(ctx context.Context, name string) (Distribution, error) {
+	hdl, err := getHandle(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "get distribution")
+	}
+
+	m, err := hdl.getMetric(name)
+	if err != nil {
+		return nil, errors.Wrapf(err, "unable to find metric %q", name)
+	}
+
+	d, ok := m.(*distribution)
+	if !ok {
+		return nil, errors.Errorf("%q is not a distribution", name)
+	}
+
+	return d, nil
+}
+
+func decodeDistribution(base metricBase, raw []byte) (*distribution, error) {
+	hdr, _, err := decodeHistogramAggHeader(raw)
+	if err != nil {
+		return nil, errors.Wrap(err, "decode d_tm_histogram_t")
+	}
+
+	return &distribution{
+		metricBase: base,
+		min:        hdr.Min,
+		max:        hdr.Max,
+		sum:        hdr.Sum,
+		count:      hdr.Count,
+	}, nil
+}
@@ -0,0 +1,92 @@
+//
+// (C) Copyright 2022 Intel Corporation.
+//
+// SPDX-License-Identifier: BSD-2-Clause-Patent
+//
+// +build linux,amd64
+//
+
+package telemetry
+
+import (
+	"encoding/binary"
+	"math"
+	"testing"
+
+	"github.com/daos-stack/daos/src/control/common"
+)
+
+func TestTelemetry_Distribution_Mean(t *testing.T) {
+	for name, tc := range map[string]struct {
+		dist   distribution
+		expect float64
+	}{
+		"no observations": {
+			dist:   distribution{},
+			expect: 0,
+		},
+		"some observations": {
+			dist:   distribution{sum: 100, count: 10, min: 1, max: 50},
+			expect: 10,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			common.AssertEqual(t, tc.dist.Mean(), tc.expect, "bad mean")
+		})
+	}
+}
+
+// Regression test for sum/count/min/max being decoded from the real
+// d_tm_histogram_t aggregate fields rather than fabricated from bucket
+// upper bounds -- a bucket-derived sum would badly overestimate an
+// aggregate whose observations all sit near the bucket floor.
+func TestTelemetry_DecodeHistogramAggHeader(t *testing.T) {
+	raw := make([]byte, histogramAggHeaderSize+8) // +8 trailing bucket bytes
+	binary.LittleEndian.PutUint64(raw[0:8], math.Float64bits(1))
+	binary.LittleEndian.PutUint64(raw[8:16], math.Float64bits(9))
+	binary.LittleEndian.PutUint64(raw[16:24], math.Float64bits(42))
+	binary.LittleEndian.PutUint64(raw[24:32], 10)
+
+	hdr, rest, err := decodeHistogramAggHeader(raw)
+	common.CmpErr(t, nil, err)
+
+	common.AssertEqual(t, hdr.Min, 1.0, "bad min")
+	common.AssertEqual(t, hdr.Max, 9.0, "bad max")
+	common.AssertEqual(t, hdr.Sum, 42.0, "bad sum")
+	common.AssertEqual(t, hdr.Count, uint64(10), "bad count")
+	common.AssertEqual(t, len(rest), 8, "header bytes should be consumed, leaving only bucket data")
+}
+
+func TestTelemetry_DecodeHistogramAggHeader_ShortRead(t *testing.T) {
+	_, _, err := decodeHistogramAggHeader(make([]byte, histogramAggHeaderSize-1))
+	if err == nil {
+		t.Fatal("expected error decoding a truncated aggregate header")
+	}
+}
+
+func TestTelemetry_DecodeHistogramBuckets(t *testing.T) {
+	raw := make([]byte, 4+2*bucketEntrySize)
+	binary.LittleEndian.PutUint32(raw[0:4], 2)
+	binary.LittleEndian.PutUint64(raw[4:12], math.Float64bits(10))
+	binary.LittleEndian.PutUint64(raw[12:20], 3)
+	binary.LittleEndian.PutUint64(raw[20:28], math.Float64bits(20))
+	binary.LittleEndian.PutUint64(raw[28:36], 1)
+
+	buckets, err := decodeHistogramBuckets(raw)
+	common.CmpErr(t, nil, err)
+
+	common.AssertEqual(t, len(buckets), 2, "bad bucket count")
+	common.AssertEqual(t, buckets[0].UpperBound, 10.0, "bad bucket[0] upper bound")
+	common.AssertEqual(t, buckets[0].Count, uint64(3), "bad bucket[0] count")
+	common.AssertEqual(t, buckets[1].UpperBound, 20.0, "bad bucket[1] upper bound")
+	common.AssertEqual(t, buckets[1].Count, uint64(1), "bad bucket[1] count")
+}
+
+func TestTelemetry_DecodeHistogramBuckets_ShortRead(t *testing.T) {
+	raw := make([]byte, 4+bucketEntrySize)
+	binary.LittleEndian.PutUint32(raw[0:4], 2) // claims 2 buckets, only room for 1
+
+	if _, err := decodeHistogramBuckets(raw); err == nil {
+		t.Fatal("expected error decoding a truncated bucket list")
+	}
+}
@@ -0,0 +1,45 @@
+//
+// (C) Copyright 2021-2022 Intel Corporation.
+//
+// SPDX-License-Identifier: BSD-2-Clause-Patent
+//
+// +build linux,amd64
+//
+
+package telemetry
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+)
+
+type gauge struct {
+	metricBase
+	cur float64
+}
+
+func (g *gauge) Value() uint64       { return uint64(g.cur) }
+func (g *gauge) FloatValue() float64 { return g.cur }
+func (g *gauge) Type() MetricType    { return MetricTypeGauge }
+
+// GetGauge fetches the gauge-typed metric named name from the handle
+// attached to ctx.
+func GetGauge(ctx context.Context, name string) (*gauge, error) {
+	hdl, err := getHandle(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "get gauge")
+	}
+
+	m, err := hdl.getMetric(name)
+	if err != nil {
+		return nil, errors.Wrapf(err, "unable to find metric %q", name)
+	}
+
+	g, ok := m.(*gauge)
+	if !ok {
+		return nil, errors.Errorf("%q is not a gauge", name)
+	}
+
+	return g, nil
+}
@@ -0,0 +1,203 @@
+//
+// (C) Copyright 2022 Intel Corporation.
+//
+// SPDX-License-Identifier: BSD-2-Clause-Patent
+//
+// +build linux,amd64
+//
+
+package telemetry
+
+import (
+	"context"
+	"encoding/binary"
+	"math"
+	"sort"
+
+	"github.com/pkg/errors"
+)
+
+// histogramAggHeader mirrors the leading min/max/sum/count fields of a
+// gurt d_tm_histogram_t, ahead of its per-bucket counts. Both Histogram
+// and Distribution metrics share this layout, so it's decoded once and
+// reused by both.
+type histogramAggHeader struct {
+	Min   float64
+	Max   float64
+	Sum   float64
+	Count uint64
+}
+
+const histogramAggHeaderSize = 8 * 4 // min, max, sum float64 + count uint64
+
+func decodeHistogramAggHeader(raw []byte) (histogramAggHeader, []byte, error) {
+	if len(raw) < histogramAggHeaderSize {
+		return histogramAggHeader{}, nil, errors.New("short read decoding d_tm_histogram_t aggregates")
+	}
+
+	hdr := histogramAggHeader{
+		Min:   math.Float64frombits(binary.LittleEndian.Uint64(raw[0:8])),
+		Max:   math.Float64frombits(binary.LittleEndian.Uint64(raw[8:16])),
+		Sum:   math.Float64frombits(binary.LittleEndian.Uint64(raw[16:24])),
+		Count: binary.LittleEndian.Uint64(raw[24:32]),
+	}
+
+	return hdr, raw[histogramAggHeaderSize:], nil
+}
+
+// Bucket is a single bucket of a Histogram: the count of observations
+// whose value fell at or below UpperBound, but above the previous
+// bucket's UpperBound.
+type Bucket struct {
+	UpperBound float64
+	Count      uint64
+}
+
+// Histogram is a distribution metric decoded from a gurt
+// d_tm_histogram_t: a fixed set of buckets plus running sum/count
+// aggregates.
+type Histogram interface {
+	Metric
+
+	// Buckets returns the histogram's buckets in ascending order of
+	// UpperBound.
+	Buckets() []Bucket
+	// Sum is the sum of all observed values.
+	Sum() float64
+	// Count is the total number of observations across all buckets.
+	Count() uint64
+	// Mean is Sum() / Count(), or 0 if Count() is 0.
+	Mean() float64
+	// Quantile estimates the value at quantile p (0 <= p <= 1) using
+	// linear interpolation within the bucket that contains it.
+	Quantile(p float64) (float64, error)
+}
+
+type histogram struct {
+	metricBase
+	buckets []Bucket
+	sum     float64
+	count   uint64
+}
+
+func (h *histogram) Type() MetricType  { return MetricTypeHistogram }
+func (h *histogram) Buckets() []Bucket { return h.buckets }
+func (h *histogram) Sum() float64      { return h.sum }
+func (h *histogram) Count() uint64     { return h.count }
+
+// Value and FloatValue satisfy Metric by exposing the observation count,
+// the one aggregate that's meaningful to truncate to an integer; use
+// Sum()/Mean()/Quantile() for the rest of the distribution.
+func (h *histogram) Value() uint64       { return h.count }
+func (h *histogram) FloatValue() float64 { return float64(h.count) }
+
+func (h *histogram) Mean() float64 {
+	if h.count == 0 {
+		return 0
+	}
+	return h.sum / float64(h.count)
+}
+
+func (h *histogram) Quantile(p float64) (float64, error) {
+	if p < 0 || p > 1 {
+		return 0, errors.Errorf("quantile %f out of range [0,1]", p)
+	}
+	if h.count == 0 {
+		return 0, errors.New("histogram has no observations")
+	}
+
+	target := p * float64(h.count)
+	var cumCount uint64
+	var prevBound float64
+
+	for _, b := range h.buckets {
+		if float64(cumCount+b.Count) >= target {
+			if b.Count == 0 {
+				return b.UpperBound, nil
+			}
+			// Linear interpolation within the bucket, assuming
+			// observations are uniformly distributed across its range.
+			frac := (target - float64(cumCount)) / float64(b.Count)
+			return prevBound + frac*(b.UpperBound-prevBound), nil
+		}
+		cumCount += b.Count
+		prevBound = b.UpperBound
+	}
+
+	return h.buckets[len(h.buckets)-1].UpperBound, nil
+}
+
+// GetHistogram fetches the histogram-typed metric named name from the
+// handle attached to ctx.
+func GetHistogram(ctx context.Context, name string) (Histogram, error) {
+	hdl, err := getHandle(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "get histogram")
+	}
+
+	m, err := hdl.getMetric(name)
+	if err != nil {
+		return nil, errors.Wrapf(err, "unable to find metric %q", name)
+	}
+
+	h, ok := m.(*histogram)
+	if !ok {
+		return nil, errors.Errorf("%q is not a histogram", name)
+	}
+
+	return h, nil
+}
+
+// bucketEntrySize is the encoded size of one bucket: an upper-bound
+// float64 followed by an observation-count uint64.
+const bucketEntrySize = 8 + 8
+
+// decodeHistogramBuckets decodes the per-bucket {upper bound, count}
+// pairs that follow a d_tm_histogram_t's aggregate header: a leading
+// uint32 bucket count, then that many bucketEntrySize-byte entries.
+func decodeHistogramBuckets(raw []byte) ([]Bucket, error) {
+	if len(raw) < 4 {
+		return nil, errors.New("short read decoding bucket count")
+	}
+
+	numBuckets := binary.LittleEndian.Uint32(raw[0:4])
+	raw = raw[4:]
+
+	want := int(numBuckets) * bucketEntrySize
+	if len(raw) < want {
+		return nil, errors.Errorf("short read decoding %d buckets", numBuckets)
+	}
+
+	buckets := make([]Bucket, numBuckets)
+	for i := range buckets {
+		off := i * bucketEntrySize
+		buckets[i] = Bucket{
+			UpperBound: math.Float64frombits(binary.LittleEndian.Uint64(raw[off : off+8])),
+			Count:      binary.LittleEndian.Uint64(raw[off+8 : off+16]),
+		}
+	}
+
+	return buckets, nil
+}
+
+func decodeHistogram(base metricBase, raw []byte) (*histogram, error) {
+	hdr, rest, err := decodeHistogramAggHeader(raw)
+	if err != nil {
+		return nil, errors.Wrap(err, "decode d_tm_histogram_t")
+	}
+
+	buckets, err := decodeHistogramBuckets(rest)
+	if err != nil {
+		return nil, errors.Wrap(err, "decode d_tm_histogram_t buckets")
+	}
+	sort.Slice(buckets, func(i, j int) bool {
+		return buckets[i].UpperBound < buckets[j].UpperBound
+	})
+
+	return &histogram{
+		metricBase: base,
+		buckets:    buckets,
+		sum:        hdr.Sum,
+		count:      hdr.Count,
+	}, nil
+}
@@ -0,0 +1,101 @@
+//
+// (C) Copyright 2022 Intel Corporation.
+//
+// SPDX-License-Identifier: BSD-2-Clause-Patent
+//
+// +build linux,amd64
+//
+
+package telemetry
+
+import (
+	"context"
+	"testing"
+
+	"github.com/daos-stack/daos/src/control/common"
+	"github.com/pkg/errors"
+)
+
+func TestTelemetry_GetHistogram(t *testing.T) {
+	testCtx, testMetrics := setupTestMetrics(t)
+	defer cleanupTestMetricsProducer(t)
+
+	realHistogram, ok := testMetrics[MetricTypeHistogram]
+	if !ok {
+		t.Fatal("real histogram not in metrics set")
+	}
+	histName := realHistogram.name
+
+	for name, tc := range map[string]struct {
+		ctx        context.Context
+		metricName string
+		expErr     error
+	}{
+		"nil ctx": {
+			metricName: histName,
+			expErr:     errors.New("nil context"),
+		},
+		"non-handle ctx": {
+			ctx:        context.TODO(),
+			metricName: histName,
+			expErr:     errors.New("no handle"),
+		},
+		"bad name": {
+			ctx:        testCtx,
+			metricName: "not_a_real_metric",
+			expErr:     errors.New("unable to find metric"),
+		},
+		"bad type": {
+			ctx:        testCtx,
+			metricName: testMetrics[MetricTypeCounter].name,
+			expErr:     errors.New("not a histogram"),
+		},
+		"success": {
+			ctx:        testCtx,
+			metricName: histName,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			result, err := GetHistogram(tc.ctx, tc.metricName)
+
+			common.CmpErr(t, tc.expErr, err)
+
+			if tc.expErr == nil {
+				if result == nil {
+					t.Fatal("expected non-nil histogram")
+				}
+				common.AssertEqual(t, result.Type(), MetricTypeHistogram, "bad type")
+			}
+		})
+	}
+}
+
+func TestTelemetry_Histogram_EmptyQuantile(t *testing.T) {
+	h := &histogram{}
+
+	if _, err := h.Quantile(0.5); err == nil {
+		t.Fatal("expected error for empty histogram")
+	}
+}
+
+func TestTelemetry_Histogram_Quantile(t *testing.T) {
+	h := &histogram{
+		buckets: []Bucket{
+			{UpperBound: 10, Count: 5},
+			{UpperBound: 20, Count: 5},
+			{UpperBound: 30, Count: 0},
+		},
+		count: 10,
+		sum:   100,
+	}
+
+	common.AssertEqual(t, h.Mean(), 10.0, "bad mean")
+
+	median, err := h.Quantile(0.5)
+	common.CmpErr(t, nil, err)
+	common.AssertEqual(t, median, 10.0, "bad median")
+
+	if _, err := h.Quantile(1.5); err == nil {
+		t.Fatal("expected error for out-of-range quantile")
+	}
+}
@@ -0,0 +1,197 @@
+//
+// (C) Copyright 2021-2022 Intel Corporation.
+//
+// SPDX-License-Identifier: BSD-2-Clause-Patent
+//
+// +build linux,amd64
+//
+
+package telemetry
+
+import (
+	"context"
+	"strings"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+// MetricType identifies the kind of value a Metric carries.
+type MetricType int
+
+const (
+	// MetricTypeCounter is a monotonically increasing value.
+	MetricTypeCounter MetricType = iota
+	// MetricTypeGauge is a value that can go up or down.
+	MetricTypeGauge
+	// MetricTypeTimestamp is a point-in-time value.
+	MetricTypeTimestamp
+	// MetricTypeHistogram is a bucketed distribution metric.
+	MetricTypeHistogram
+	// MetricTypeDistribution is a min/max/sum/count aggregate metric.
+	MetricTypeDistribution
+)
+
+// Metric is a single named value read from a DAOS engine's metric tree.
+type Metric interface {
+	// Type is the kind of value this metric carries.
+	Type() MetricType
+	// Name is the metric's base name, e.g. "update".
+	Name() string
+	// Path is the metric's full path within the tree, e.g.
+	// "/rank/0/pool/<uuid>/ops/update".
+	Path() string
+	// Desc is a short human-readable description of the metric.
+	Desc() string
+	// Units is the metric's unit of measure, if any (e.g. "bytes").
+	Units() string
+	// Value is the metric's current value, truncated to an integer for
+	// metric types (e.g. counters) for which that's meaningful.
+	Value() uint64
+	// FloatValue is the metric's current value without truncation.
+	FloatValue() float64
+}
+
+// metricBase holds the fields common to every concrete metric type.
+type metricBase struct {
+	name  string
+	path  string
+	desc  string
+	units string
+}
+
+func (m metricBase) Name() string  { return m.name }
+func (m metricBase) Path() string  { return m.path }
+func (m metricBase) Desc() string  { return m.desc }
+func (m metricBase) Units() string { return m.units }
+
+// node is one entry in a handle's in-memory metric tree: either a
+// directory (children populated, metric nil) or a leaf (metric set).
+type node struct {
+	children map[string]*node
+	metric   Metric
+}
+
+// handle is a connection to a single engine's metric tree, attached to a
+// context by Init and retrieved by getHandle.
+type handle struct {
+	mu   sync.RWMutex
+	root *node
+}
+
+func newHandle() *handle {
+	return &handle{root: &node{children: make(map[string]*node)}}
+}
+
+// addMetric inserts m into the tree at path, creating any intermediate
+// directories that don't already exist.
+func (h *handle) addMetric(path string, m Metric) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	n := h.root
+	for _, part := range splitPath(path) {
+		child, ok := n.children[part]
+		if !ok {
+			child = &node{children: make(map[string]*node)}
+			n.children[part] = child
+		}
+		n = child
+	}
+	n.metric = m
+}
+
+func (h *handle) lookup(path string) (*node, error) {
+	n := h.root
+	for _, part := range splitPath(path) {
+		child, ok := n.children[part]
+		if !ok {
+			return nil, errors.Errorf("path %q not found", path)
+		}
+		n = child
+	}
+	return n, nil
+}
+
+// getMetric returns the metric at path, or an error if path doesn't lead
+// to a metric (including if it's a directory).
+func (h *handle) getMetric(path string) (Metric, error) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	n, err := h.lookup(path)
+	if err != nil {
+		return nil, err
+	}
+	if n.metric == nil {
+		return nil, errors.Errorf("%q is a directory, not a metric", path)
+	}
+	return n.metric, nil
+}
+
+// isDir reports whether path leads to a directory rather than a metric.
+func (h *handle) isDir(path string) bool {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	n, err := h.lookup(path)
+	if err != nil {
+		return false
+	}
+	return n.metric == nil
+}
+
+// listChildren returns the immediate child names of the directory at
+// dirPath.
+func (h *handle) listChildren(dirPath string) ([]string, error) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	n, err := h.lookup(dirPath)
+	if err != nil {
+		return nil, err
+	}
+
+	names := make([]string, 0, len(n.children))
+	for name := range n.children {
+		names = append(names, name)
+	}
+	return names, nil
+}
+
+func splitPath(path string) []string {
+	path = strings.Trim(path, "/")
+	if path == "" {
+		return nil
+	}
+	return strings.Split(path, "/")
+}
+
+type handleCtxKey struct{}
+
+// getHandle returns the handle attached to ctx by Init.
+func getHandle(ctx context.Context) (*handle, error) {
+	if ctx == nil {
+		return nil, errors.New("nil context")
+	}
+
+	hdl, ok := ctx.Value(handleCtxKey{}).(*handle)
+	if !ok || hdl == nil {
+		return nil, errors.New("no handle")
+	}
+	return hdl, nil
+}
+
+// Init attaches a new, empty in-memory metric tree handle to the returned
+// context. Callers retrieve the handle implicitly by passing that context
+// to the rest of this package's functions (GetCounter, Query, Walk, ...).
+// The rank argument is not used: Init does not attach to any engine's
+// shared-memory metric tree, so the handle holds only metrics added to it
+// in-process.
+func Init(parent context.Context, rank uint32) (context.Context, error) {
+	if parent == nil {
+		return nil, errors.New("nil context")
+	}
+
+	return context.WithValue(parent, handleCtxKey{}, newHandle()), nil
+}
@@ -0,0 +1,108 @@
+//
+// (C) Copyright 2021-2022 Intel Corporation.
+//
+// SPDX-License-Identifier: BSD-2-Clause-Patent
+//
+// +build linux,amd64
+//
+
+package telemetry
+
+import (
+	"context"
+	"testing"
+
+	"github.com/daos-stack/daos/src/control/common"
+)
+
+// testRank is the rank Init is called with by setupTestMetrics. Its value
+// doesn't matter to the in-memory test handle; it just has to be a valid
+// argument.
+const testRank = 0
+
+// testMetric records both the metadata a test metric was created with and
+// the values a lookup of it is expected to return, so test tables can
+// assert against it without duplicating literals.
+type testMetric struct {
+	name  string
+	desc  string
+	units string
+	cur   float64
+}
+
+// setupTestMetrics opens a test handle via Init and populates it with one
+// root-level metric per type exercised by this package's tests, returning
+// the attached context and a lookup table keyed by MetricType. Callers
+// should defer cleanupTestMetricsProducer(tb).
+func setupTestMetrics(tb testing.TB) (context.Context, map[MetricType]*testMetric) {
+	tb.Helper()
+
+	ctx, err := Init(context.Background(), testRank)
+	if err != nil {
+		tb.Fatalf("Init(): %s", err)
+	}
+
+	hdl, err := getHandle(ctx)
+	if err != nil {
+		tb.Fatalf("getHandle(): %s", err)
+	}
+
+	defs := map[MetricType]*testMetric{
+		MetricTypeCounter: {
+			name: "test_counter", desc: "a test counter", units: "ops", cur: 42,
+		},
+		MetricTypeGauge: {
+			name: "test_gauge", desc: "a test gauge", units: "bytes", cur: 17,
+		},
+		MetricTypeHistogram: {
+			name: "test_histogram", desc: "a test histogram", units: "us",
+		},
+	}
+
+	for typ, tm := range defs {
+		base := metricBase{name: tm.name, path: tm.name, desc: tm.desc, units: tm.units}
+
+		var m Metric
+		switch typ {
+		case MetricTypeCounter:
+			m = &counter{metricBase: base, cur: uint64(tm.cur)}
+		case MetricTypeGauge:
+			m = &gauge{metricBase: base, cur: tm.cur}
+		case MetricTypeHistogram:
+			m = &histogram{
+				metricBase: base,
+				buckets: []Bucket{
+					{UpperBound: 10, Count: 3},
+					{UpperBound: 20, Count: 2},
+				},
+				sum:   50,
+				count: 5,
+			}
+		}
+
+		hdl.addMetric(tm.name, m)
+	}
+
+	return ctx, defs
+}
+
+// cleanupTestMetricsProducer releases any resources setupTestMetrics
+// acquired. The in-memory test handle owns nothing external, but this
+// mirrors the teardown a real shared-memory-backed metrics producer
+// would need between tests.
+func cleanupTestMetricsProducer(tb testing.TB) {
+	tb.Helper()
+}
+
+func TestTelemetry_Init(t *testing.T) {
+	if _, err := Init(nil, testRank); err == nil {
+		t.Fatal("expected Init to reject a nil context")
+	}
+
+	ctx, err := Init(context.Background(), testRank)
+	common.CmpErr(t, nil, err)
+
+	if _, err := getHandle(ctx); err != nil {
+		t.Fatalf("getHandle() on an Init'd context: %s", err)
+	}
+}
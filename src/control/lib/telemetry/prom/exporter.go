@@ -0,0 +1,215 @@
+//
+// (C) Copyright 2022 Intel Corporation.
+//
+// SPDX-License-Identifier: BSD-2-Clause-Patent
+//
+// +build linux,amd64
+//
+
+// Package prom exposes DAOS telemetry in the Prometheus text exposition
+// format, so that a standard Prometheus server can scrape engine/rank
+// metrics without going through the DAOS CLI tooling.
+package prom
+
+import (
+	"context"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/daos-stack/daos/src/control/lib/telemetry"
+)
+
+// leadingDigit matches a Prometheus identifier that starts with a digit,
+// which isn't legal; a leading underscore is prefixed to fix it up.
+var leadingDigit = regexp.MustCompile(`^[0-9]`)
+
+var invalidNameChar = regexp.MustCompile(`[^a-zA-Z0-9_:]`)
+
+// toPromName translates a DAOS metric path (e.g. "/rank/0/pool/<uuid>/ops/update/latency")
+// into a valid Prometheus metric name, replacing path separators and any
+// other disallowed characters with underscores.
+func toPromName(path string) string {
+	name := invalidNameChar.ReplaceAllString(strings.Trim(path, "/"), "_")
+	if leadingDigit.MatchString(name) {
+		name = "_" + name
+	}
+	return name
+}
+
+// CollectorOpts configures an Exporter.
+type CollectorOpts struct {
+	// Namespace is prepended to every metric name (e.g. "engine").
+	Namespace string
+}
+
+// Exporter implements prometheus.Collector by walking a DAOS metric tree
+// via telemetry.CollectMetrics and translating each metric it finds into
+// the equivalent Prometheus Counter, Gauge or Histogram.
+type Exporter struct {
+	ctx     context.Context
+	ns      string
+	collect func(ctx context.Context, visit telemetry.MetricVisitor) error
+}
+
+// NewExporter creates an Exporter that reads metrics from the handle
+// attached to ctx.
+func NewExporter(ctx context.Context, opts *CollectorOpts) *Exporter {
+	if opts == nil {
+		opts = &CollectorOpts{}
+	}
+	return &Exporter{
+		ctx:     ctx,
+		ns:      opts.Namespace,
+		collect: telemetry.CollectMetrics,
+	}
+}
+
+// Describe implements prometheus.Collector. DAOS metrics can appear or
+// disappear as engines start pools, so we deliberately send nothing on
+// ch -- this makes the exporter an "unchecked" collector.
+func (e *Exporter) Describe(ch chan<- *prometheus.Desc) {}
+
+// Collect implements prometheus.Collector.
+func (e *Exporter) Collect(ch chan<- prometheus.Metric) {
+	err := e.collect(e.ctx, func(ctx context.Context, m telemetry.Metric) error {
+		pm, err := e.toPromMetric(m)
+		if err != nil {
+			// Not every DAOS metric type (e.g. timestamps) has a
+			// sensible Prometheus equivalent; skip rather than fail
+			// the whole scrape.
+			return nil
+		}
+		ch <- pm
+		return nil
+	})
+	if err != nil {
+		// Collect() has no error return; surfacing a failed scrape as a
+		// special metric is the convention prometheus/client_golang itself
+		// recommends for collectors that can fail.
+		ch <- prometheus.NewInvalidMetric(
+			prometheus.NewDesc("daos_scrape_error", "error collecting DAOS metrics", nil, nil),
+			errors.Wrap(err, "collect metrics"),
+		)
+	}
+}
+
+func (e *Exporter) toPromMetric(m telemetry.Metric) (prometheus.Metric, error) {
+	// pathLabels pulls the rank/engine/pool components out as labels, so
+	// they must also be stripped from the path before it's turned into a
+	// metric name -- otherwise every rank and pool mints its own metric
+	// name instead of sharing one name distinguished by label, which is
+	// both contrary to the point of the labels and unbounded cardinality
+	// for a long-running scrape target.
+	name := toPromName(stripLabeledSegments(m.Path()))
+	if e.ns != "" {
+		name = e.ns + "_" + name
+	}
+
+	labels, labelVals := pathLabels(m.Path())
+	desc := prometheus.NewDesc(name, helpText(m), labels, nil)
+
+	switch m.Type() {
+	case telemetry.MetricTypeCounter:
+		return prometheus.NewConstMetric(desc, prometheus.CounterValue, m.FloatValue(), labelVals...)
+	case telemetry.MetricTypeGauge:
+		return prometheus.NewConstMetric(desc, prometheus.GaugeValue, m.FloatValue(), labelVals...)
+	case telemetry.MetricTypeHistogram:
+		h, ok := m.(telemetry.Histogram)
+		if !ok {
+			return nil, errors.Errorf("%s: histogram metric did not implement telemetry.Histogram", m.Path())
+		}
+		return prometheus.NewConstHistogram(desc, h.Count(), h.Sum(), cumulativeBuckets(h), labelVals...)
+	default:
+		return nil, errors.Errorf("%s: no Prometheus equivalent for metric type %v", m.Path(), m.Type())
+	}
+}
+
+// cumulativeBuckets converts a Histogram's per-bucket counts into the
+// cumulative "observations <= bound" form prometheus.NewConstHistogram
+// expects.
+func cumulativeBuckets(h telemetry.Histogram) map[float64]uint64 {
+	buckets := make(map[float64]uint64, len(h.Buckets()))
+
+	var cumulative uint64
+	for _, b := range h.Buckets() {
+		cumulative += b.Count
+		buckets[b.UpperBound] = cumulative
+	}
+
+	return buckets
+}
+
+func helpText(m telemetry.Metric) string {
+	desc := m.Desc()
+	if desc == "" {
+		desc = m.Name()
+	}
+	if units := m.Units(); units != "" {
+		desc = desc + " (" + units + ")"
+	}
+	return desc
+}
+
+// pathLabels pulls the rank, engine and pool components out of a DAOS
+// metric path, e.g. "/rank/3/pool/<uuid>/ops/update/latency", and returns
+// them as parallel label name/value slices suitable for
+// prometheus.NewDesc/NewConstMetric.
+func pathLabels(path string) ([]string, []string) {
+	var names, vals []string
+	parts := strings.Split(strings.Trim(path, "/"), "/")
+
+	for i := 0; i+1 < len(parts); i++ {
+		switch parts[i] {
+		case "rank":
+			if _, err := strconv.Atoi(parts[i+1]); err == nil {
+				names = append(names, "rank")
+				vals = append(vals, parts[i+1])
+			}
+		case "engine":
+			if _, err := strconv.Atoi(parts[i+1]); err == nil {
+				names = append(names, "engine")
+				vals = append(vals, parts[i+1])
+			}
+		case "pool":
+			names = append(names, "pool")
+			vals = append(vals, parts[i+1])
+		}
+	}
+
+	return names, vals
+}
+
+// stripLabeledSegments removes the same rank/<n>, engine/<n> and
+// pool/<uuid> path components that pathLabels extracts as labels,
+// leaving the rank/engine/pool-independent remainder (e.g.
+// "/ops/update/latency") to be turned into the metric name. It must
+// agree exactly with pathLabels on which segments are label-bearing, or
+// a path component ends up dropped from both the name and the labels.
+func stripLabeledSegments(path string) string {
+	parts := strings.Split(strings.Trim(path, "/"), "/")
+
+	kept := make([]string, 0, len(parts))
+	for i := 0; i < len(parts); i++ {
+		switch parts[i] {
+		case "rank", "engine":
+			if i+1 < len(parts) {
+				if _, err := strconv.Atoi(parts[i+1]); err == nil {
+					i++
+					continue
+				}
+			}
+		case "pool":
+			if i+1 < len(parts) {
+				i++
+				continue
+			}
+		}
+		kept = append(kept, parts[i])
+	}
+
+	return strings.Join(kept, "/")
+}
@@ -0,0 +1,187 @@
+//
+// (C) Copyright 2022 Intel Corporation.
+//
+// SPDX-License-Identifier: BSD-2-Clause-Patent
+//
+// +build linux,amd64
+//
+
+package prom
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+
+	"github.com/daos-stack/daos/src/control/lib/telemetry"
+)
+
+type fakeMetric struct {
+	path  string
+	name  string
+	desc  string
+	units string
+	typ   telemetry.MetricType
+	value float64
+}
+
+func (f *fakeMetric) Type() telemetry.MetricType { return f.typ }
+func (f *fakeMetric) Name() string               { return f.name }
+func (f *fakeMetric) Path() string               { return f.path }
+func (f *fakeMetric) Desc() string               { return f.desc }
+func (f *fakeMetric) Units() string              { return f.units }
+func (f *fakeMetric) Value() uint64              { return uint64(f.value) }
+func (f *fakeMetric) FloatValue() float64        { return f.value }
+
+type fakeHistogram struct {
+	fakeMetric
+	buckets []telemetry.Bucket
+	sum     float64
+	count   uint64
+}
+
+func (f *fakeHistogram) Buckets() []telemetry.Bucket { return f.buckets }
+func (f *fakeHistogram) Sum() float64                { return f.sum }
+func (f *fakeHistogram) Count() uint64               { return f.count }
+func (f *fakeHistogram) Mean() float64               { return f.sum / float64(f.count) }
+func (f *fakeHistogram) Quantile(p float64) (float64, error) {
+	return 0, nil
+}
+
+func TestProm_ToPromName(t *testing.T) {
+	for name, tc := range map[string]struct {
+		path   string
+		expect string
+	}{
+		"simple":        {"/rank/0/ops", "rank_0_ops"},
+		"leading digit": {"0_engine/started", "_0_engine_started"},
+		"illegal chars": {"pool/abc-123/latency", "pool_abc_123_latency"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			if got := toPromName(tc.path); got != tc.expect {
+				t.Fatalf("toPromName(%q) = %q, want %q", tc.path, got, tc.expect)
+			}
+		})
+	}
+}
+
+func TestProm_StripLabeledSegments(t *testing.T) {
+	for name, tc := range map[string]struct {
+		path   string
+		expect string
+	}{
+		"rank and pool":           {"/rank/1/pool/abc/ops/update", "ops/update"},
+		"rank only":               {"/rank/1/ops/update/latency", "ops/update/latency"},
+		"non-numeric engine kept": {"/rank/1/engine/started", "engine/started"},
+		"numeric engine stripped": {"/rank/1/engine/0/started", "started"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			if got := stripLabeledSegments(tc.path); got != tc.expect {
+				t.Fatalf("stripLabeledSegments(%q) = %q, want %q", tc.path, got, tc.expect)
+			}
+		})
+	}
+}
+
+func TestProm_Collect_SharesNameAcrossRanksAndPools(t *testing.T) {
+	metrics := []telemetry.Metric{
+		&fakeMetric{
+			path: "/rank/0/pool/abc/ops/update", name: "update",
+			desc: "update ops", units: "ops", typ: telemetry.MetricTypeCounter, value: 1,
+		},
+		&fakeMetric{
+			path: "/rank/1/pool/def/ops/update", name: "update",
+			desc: "update ops", units: "ops", typ: telemetry.MetricTypeCounter, value: 2,
+		},
+	}
+
+	e := NewExporter(context.Background(), &CollectorOpts{Namespace: "daos"})
+	e.collect = func(ctx context.Context, visit telemetry.MetricVisitor) error {
+		for _, m := range metrics {
+			if err := visit(ctx, m); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	expected := `
+# HELP daos_ops_update update ops (ops)
+# TYPE daos_ops_update counter
+daos_ops_update{pool="abc",rank="0"} 1
+daos_ops_update{pool="def",rank="1"} 2
+`
+	if err := testutil.CollectAndCompare(e, strings.NewReader(expected)); err != nil {
+		t.Fatalf("unexpected collected metrics: %s", err)
+	}
+}
+
+func TestProm_Collect(t *testing.T) {
+	metrics := []telemetry.Metric{
+		&fakeMetric{
+			path: "/rank/1/pool/abc/ops/update", name: "update",
+			desc: "update ops", units: "ops", typ: telemetry.MetricTypeCounter, value: 42,
+		},
+		&fakeMetric{
+			path: "/rank/1/engine/started", name: "started",
+			desc: "engine uptime", units: "s", typ: telemetry.MetricTypeGauge, value: 100,
+		},
+	}
+
+	e := NewExporter(context.Background(), &CollectorOpts{Namespace: "daos"})
+	e.collect = func(ctx context.Context, visit telemetry.MetricVisitor) error {
+		for _, m := range metrics {
+			if err := visit(ctx, m); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	expected := `
+# HELP daos_engine_started engine uptime (s)
+# TYPE daos_engine_started gauge
+daos_engine_started{rank="1"} 100
+# HELP daos_ops_update update ops (ops)
+# TYPE daos_ops_update counter
+daos_ops_update{pool="abc",rank="1"} 42
+`
+	if err := testutil.CollectAndCompare(e, strings.NewReader(expected)); err != nil {
+		t.Fatalf("unexpected collected metrics: %s", err)
+	}
+}
+
+func TestProm_Collect_Histogram(t *testing.T) {
+	hist := &fakeHistogram{
+		fakeMetric: fakeMetric{
+			path: "/rank/1/ops/update/latency", name: "latency",
+			desc: "update latency", units: "us", typ: telemetry.MetricTypeHistogram,
+		},
+		buckets: []telemetry.Bucket{
+			{UpperBound: 10, Count: 2},
+			{UpperBound: 20, Count: 1},
+		},
+		sum:   35,
+		count: 3,
+	}
+
+	e := NewExporter(context.Background(), &CollectorOpts{Namespace: "daos"})
+	e.collect = func(ctx context.Context, visit telemetry.MetricVisitor) error {
+		return visit(ctx, hist)
+	}
+
+	expected := `
+# HELP daos_ops_update_latency update latency (us)
+# TYPE daos_ops_update_latency histogram
+daos_ops_update_latency_bucket{rank="1",le="10"} 2
+daos_ops_update_latency_bucket{rank="1",le="20"} 3
+daos_ops_update_latency_bucket{rank="1",le="+Inf"} 3
+daos_ops_update_latency_sum{rank="1"} 35
+daos_ops_update_latency_count{rank="1"} 3
+`
+	if err := testutil.CollectAndCompare(e, strings.NewReader(expected)); err != nil {
+		t.Fatalf("unexpected collected metrics: %s", err)
+	}
+}
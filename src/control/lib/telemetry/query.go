@@ -0,0 +1,181 @@
+//
+// (C) Copyright 2022 Intel Corporation.
+//
+// SPDX-License-Identifier: BSD-2-Clause-Patent
+//
+// +build linux,amd64
+//
+
+package telemetry
+
+import (
+	"context"
+	"path"
+	"regexp"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// MetricTypeMask is a bitmask of MetricType values, used by Filter to
+// select which kinds of metric a Query should return.
+type MetricTypeMask uint32
+
+const (
+	// MetricTypeMaskCounter selects counter metrics.
+	MetricTypeMaskCounter MetricTypeMask = 1 << iota
+	// MetricTypeMaskGauge selects gauge metrics.
+	MetricTypeMaskGauge
+	// MetricTypeMaskHistogram selects histogram metrics.
+	MetricTypeMaskHistogram
+	// MetricTypeMaskTimestamp selects timestamp metrics.
+	MetricTypeMaskTimestamp
+	// MetricTypeMaskDistribution selects distribution metrics.
+	MetricTypeMaskDistribution
+
+	// MetricTypeMaskAny matches every metric type.
+	MetricTypeMaskAny = MetricTypeMaskCounter | MetricTypeMaskGauge |
+		MetricTypeMaskHistogram | MetricTypeMaskTimestamp | MetricTypeMaskDistribution
+)
+
+func maskFor(t MetricType) MetricTypeMask {
+	switch t {
+	case MetricTypeCounter:
+		return MetricTypeMaskCounter
+	case MetricTypeGauge:
+		return MetricTypeMaskGauge
+	case MetricTypeHistogram:
+		return MetricTypeMaskHistogram
+	case MetricTypeTimestamp:
+		return MetricTypeMaskTimestamp
+	case MetricTypeDistribution:
+		return MetricTypeMaskDistribution
+	default:
+		return 0
+	}
+}
+
+// Filter narrows down a Query to a subset of the metric tree: callers
+// describe *what they're after* instead of having to know exact metric
+// names and types up front.
+type Filter struct {
+	// NameGlob, if set, is matched against each metric's base name using
+	// path.Match semantics (e.g. "*_latency").
+	NameGlob string
+	// PathPrefix, if set, restricts the walk to metrics whose full path
+	// starts with this prefix (e.g. "/rank/0/pool/<uuid>").
+	PathPrefix string
+	// Types restricts results to the given metric types. Zero means
+	// MetricTypeMaskAny.
+	Types MetricTypeMask
+	// Regexp, if set, is matched against each metric's full path in
+	// addition to NameGlob/PathPrefix.
+	Regexp *regexp.Regexp
+}
+
+func (f *Filter) matches(m Metric) (bool, error) {
+	if f.Types != 0 && f.Types&maskFor(m.Type()) == 0 {
+		return false, nil
+	}
+
+	if f.PathPrefix != "" {
+		if !hasPathPrefix(m.Path(), f.PathPrefix) {
+			return false, nil
+		}
+	}
+
+	if f.NameGlob != "" {
+		ok, err := path.Match(f.NameGlob, m.Name())
+		if err != nil {
+			return false, errors.Wrapf(err, "bad name glob %q", f.NameGlob)
+		}
+		if !ok {
+			return false, nil
+		}
+	}
+
+	if f.Regexp != nil && !f.Regexp.MatchString(m.Path()) {
+		return false, nil
+	}
+
+	return true, nil
+}
+
+// hasPathPrefix reports whether p is prefix, or a descendant of prefix in
+// the metric tree. A plain strings.HasPrefix isn't enough on its own:
+// "/rank/1" is a string-prefix of "/rank/10" despite being a different
+// path entirely, so we additionally require the prefix to end exactly at
+// a path boundary.
+func hasPathPrefix(p, prefix string) bool {
+	if !strings.HasPrefix(p, prefix) {
+		return false
+	}
+	if len(p) == len(prefix) || strings.HasSuffix(prefix, "/") {
+		return true
+	}
+	return p[len(prefix)] == '/'
+}
+
+// Results holds the already-materialized metric snapshots gathered by a
+// single Query, split out by type so a caller can fetch just the
+// counters, just the gauges, and so on.
+type Results struct {
+	counters      []Metric
+	gauges        []Metric
+	histograms    []Metric
+	timestamps    []Metric
+	distributions []Metric
+}
+
+// Counters returns every counter metric matched by the query.
+func (r *Results) Counters() []Metric { return r.counters }
+
+// Gauges returns every gauge metric matched by the query.
+func (r *Results) Gauges() []Metric { return r.gauges }
+
+// Histograms returns every histogram metric matched by the query.
+func (r *Results) Histograms() []Metric { return r.histograms }
+
+// Timestamps returns every timestamp metric matched by the query.
+func (r *Results) Timestamps() []Metric { return r.timestamps }
+
+// Distributions returns every distribution metric matched by the query.
+func (r *Results) Distributions() []Metric { return r.distributions }
+
+// Query walks the metric tree reachable from the handle attached to ctx
+// exactly once, applying filter and bucketing every matching metric by
+// type into the returned Results. This replaces the pattern where a
+// caller has to already know a metric's exact name and type (as
+// GetCounter/GetGauge require) before it can be fetched.
+func Query(ctx context.Context, filter Filter) (*Results, error) {
+	res := &Results{}
+
+	err := CollectMetrics(ctx, func(ctx context.Context, m Metric) error {
+		ok, err := filter.matches(m)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return nil
+		}
+
+		switch m.Type() {
+		case MetricTypeCounter:
+			res.counters = append(res.counters, m)
+		case MetricTypeGauge:
+			res.gauges = append(res.gauges, m)
+		case MetricTypeHistogram:
+			res.histograms = append(res.histograms, m)
+		case MetricTypeTimestamp:
+			res.timestamps = append(res.timestamps, m)
+		case MetricTypeDistribution:
+			res.distributions = append(res.distributions, m)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "query metrics")
+	}
+
+	return res, nil
+}
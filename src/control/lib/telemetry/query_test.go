@@ -0,0 +1,88 @@
+//
+// (C) Copyright 2022 Intel Corporation.
+//
+// SPDX-License-Identifier: BSD-2-Clause-Patent
+//
+// +build linux,amd64
+//
+
+package telemetry
+
+import (
+	"testing"
+
+	"github.com/daos-stack/daos/src/control/common"
+	"github.com/pkg/errors"
+)
+
+func TestTelemetry_HasPathPrefix(t *testing.T) {
+	for name, tc := range map[string]struct {
+		path   string
+		prefix string
+		expect bool
+	}{
+		"exact match":                      {"/rank/1", "/rank/1", true},
+		"true descendant":                  {"/rank/1/pool/abc", "/rank/1", true},
+		"sibling with shared digit prefix": {"/rank/10", "/rank/1", false},
+		"sibling deeper tree":              {"/rank/10/pool/abc", "/rank/1", false},
+		"prefix with trailing slash":       {"/rank/1/pool/abc", "/rank/1/", true},
+		"not a prefix at all":              {"/pool/abc", "/rank/1", false},
+	} {
+		t.Run(name, func(t *testing.T) {
+			common.AssertEqual(t, hasPathPrefix(tc.path, tc.prefix), tc.expect, "bad result")
+		})
+	}
+}
+
+func TestTelemetry_Query(t *testing.T) {
+	testCtx, testMetrics := setupTestMetrics(t)
+	defer cleanupTestMetricsProducer(t)
+
+	counter := testMetrics[MetricTypeCounter]
+	gauge := testMetrics[MetricTypeGauge]
+
+	for name, tc := range map[string]struct {
+		filter   Filter
+		expNames []string
+		expErr   error
+	}{
+		"no filter matches everything": {
+			filter:   Filter{},
+			expNames: []string{counter.name, gauge.name},
+		},
+		"type mask restricts to counters": {
+			filter:   Filter{Types: MetricTypeMaskCounter},
+			expNames: []string{counter.name},
+		},
+		"name glob": {
+			filter:   Filter{NameGlob: gauge.name},
+			expNames: []string{gauge.name},
+		},
+		"bad name glob": {
+			filter: Filter{NameGlob: "["},
+			expErr: errors.New("syntax error"),
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			res, err := Query(testCtx, tc.filter)
+
+			if tc.expErr != nil {
+				if err == nil {
+					t.Fatal("expected error, got nil")
+				}
+				return
+			}
+			common.CmpErr(t, nil, err)
+
+			var got []string
+			for _, m := range res.Counters() {
+				got = append(got, m.Name())
+			}
+			for _, m := range res.Gauges() {
+				got = append(got, m.Name())
+			}
+
+			common.AssertEqual(t, len(got), len(tc.expNames), "unexpected number of results")
+		})
+	}
+}
@@ -0,0 +1,136 @@
+//
+// (C) Copyright 2022 Intel Corporation.
+//
+// SPDX-License-Identifier: BSD-2-Clause-Patent
+//
+// +build linux,amd64
+//
+
+package telemetry
+
+import (
+	"context"
+	"path"
+	"sort"
+
+	"github.com/pkg/errors"
+)
+
+// SkipDir is used as a return value from WalkFunc to indicate that the
+// directory named in the call is to be skipped, mirroring
+// filepath.SkipDir. Walk does not return it for a Directory's call; if
+// WalkFunc returns it for a metric instead, the walk is aborted and Walk
+// returns SkipDir like any other error.
+var SkipDir = errors.New("skip this directory")
+
+// WalkFunc is the type of function called for each directory and metric
+// visited by Walk. The path argument is the full hierarchical path of
+// the node (e.g. "/rank/0/pool/<uuid>"), relative to the tree root.
+type WalkFunc func(ctx context.Context, path string, m Metric, d *Directory) error
+
+// Directory represents a non-leaf node in the shared-memory metric tree,
+// e.g. an engine, pool, container or target directory.
+type Directory struct {
+	path string
+}
+
+// Path returns the directory's full hierarchical path.
+func (d *Directory) Path() string { return d.path }
+
+// Name returns the directory's base name.
+func (d *Directory) Name() string { return path.Base(d.path) }
+
+// metricAtPath wraps a Metric looked up mid-walk so that Path() is
+// guaranteed to return the node's full hierarchical path as computed by
+// the walk, regardless of what path (if any) the underlying lookup
+// populated the metric with.
+type metricAtPath struct {
+	Metric
+	path string
+}
+
+// Path overrides the embedded Metric's Path() with the walk-computed
+// hierarchical path.
+func (m *metricAtPath) Path() string { return m.path }
+
+// Walk walks the metric tree rooted at root (use "/" for the whole tree),
+// calling fn once for every directory and metric it encounters, in
+// deterministic lexicographic order of child name. If fn returns
+// SkipDir when called for a Directory, Walk skips that directory's
+// children entirely; any other non-nil error aborts the walk and is
+// returned by Walk.
+func Walk(ctx context.Context, root string, fn WalkFunc) error {
+	hdl, err := getHandle(ctx)
+	if err != nil {
+		return errors.Wrap(err, "walk metrics")
+	}
+
+	return walkDir(ctx, hdl, root, fn)
+}
+
+func walkDir(ctx context.Context, hdl *handle, dirPath string, fn WalkFunc) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	children, err := hdl.listChildren(dirPath)
+	if err != nil {
+		return errors.Wrapf(err, "list children of %q", dirPath)
+	}
+	sort.Strings(children)
+
+	for _, name := range children {
+		// Checked per-child, not just once per directory, so a flat
+		// directory holding many metrics still responds promptly to
+		// ctx cancellation instead of running to completion first.
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		childPath := path.Join(dirPath, name)
+
+		if hdl.isDir(childPath) {
+			d := &Directory{path: childPath}
+			err := fn(ctx, childPath, nil, d)
+			if err == SkipDir {
+				continue
+			}
+			if err != nil {
+				return err
+			}
+			if err := walkDir(ctx, hdl, childPath, fn); err != nil {
+				return err
+			}
+			continue
+		}
+
+		m, err := hdl.getMetric(childPath)
+		if err != nil {
+			return errors.Wrapf(err, "read metric %q", childPath)
+		}
+		if err := fn(ctx, childPath, &metricAtPath{Metric: m, path: childPath}, nil); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// List returns the names of the immediate children of the directory at
+// prefix, in lexicographic order, without descending into them. This
+// lets a caller enumerate a namespace (e.g. every pool UUID under an
+// engine) without needing to know any metric names ahead of time.
+func List(ctx context.Context, prefix string) ([]string, error) {
+	hdl, err := getHandle(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "list metrics")
+	}
+
+	children, err := hdl.listChildren(prefix)
+	if err != nil {
+		return nil, errors.Wrapf(err, "list children of %q", prefix)
+	}
+
+	sort.Strings(children)
+	return children, nil
+}
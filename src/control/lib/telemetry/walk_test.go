@@ -0,0 +1,133 @@
+//
+// (C) Copyright 2022 Intel Corporation.
+//
+// SPDX-License-Identifier: BSD-2-Clause-Patent
+//
+// +build linux,amd64
+//
+
+package telemetry
+
+import (
+	"context"
+	"testing"
+
+	"github.com/daos-stack/daos/src/control/common"
+)
+
+func TestTelemetry_Walk(t *testing.T) {
+	testCtx, testMetrics := setupTestMetrics(t)
+	defer cleanupTestMetricsProducer(t)
+
+	gotTypes := make(map[MetricType]bool)
+
+	var gotPaths []string
+	err := Walk(testCtx, "/", func(ctx context.Context, path string, m Metric, d *Directory) error {
+		if m != nil {
+			gotPaths = append(gotPaths, path)
+			gotTypes[m.Type()] = true
+
+			// The headline feature of Walk is that Metric.Path()
+			// reflects the node's real hierarchical location, not
+			// just the bare name it happens to have been looked up
+			// by -- verify the two agree for every visited metric.
+			common.AssertEqual(t, m.Path(), path, "metric Path() did not match walked path")
+		}
+		return nil
+	})
+	common.CmpErr(t, nil, err)
+
+	common.AssertEqual(t, len(gotPaths), len(testMetrics), "unexpected number of metrics visited")
+
+	// Walk reaches a handle's concrete counter/gauge/histogram metrics
+	// through the same hdl.getMetric path GetCounter/GetHistogram use, so
+	// every type registered by setupTestMetrics should show up exactly
+	// once here too.
+	for typ := range testMetrics {
+		if !gotTypes[typ] {
+			t.Errorf("Walk never visited a metric of type %v", typ)
+		}
+	}
+}
+
+// fakeMetric is a minimal Metric implementation for exercising wrapper
+// types like metricAtPath in isolation, without a real handle.
+type fakeMetric struct {
+	name string
+	typ  MetricType
+}
+
+func (f *fakeMetric) Type() MetricType    { return f.typ }
+func (f *fakeMetric) Name() string        { return f.name }
+func (f *fakeMetric) Path() string        { return f.name }
+func (f *fakeMetric) Desc() string        { return "" }
+func (f *fakeMetric) Units() string       { return "" }
+func (f *fakeMetric) Value() uint64       { return 0 }
+func (f *fakeMetric) FloatValue() float64 { return 0 }
+
+func TestTelemetry_MetricAtPath(t *testing.T) {
+	base := &fakeMetric{name: "foo", typ: MetricTypeCounter}
+	wrapped := &metricAtPath{Metric: base, path: "/rank/0/foo"}
+
+	common.AssertEqual(t, wrapped.Path(), "/rank/0/foo", "Path() was not overridden")
+	common.AssertEqual(t, wrapped.Name(), base.name, "Name() should still be forwarded to the embedded Metric")
+	common.AssertEqual(t, wrapped.Type(), MetricTypeCounter, "Type() should still be forwarded to the embedded Metric")
+}
+
+func TestTelemetry_Walk_CancelMidWalk(t *testing.T) {
+	testCtx, testMetrics := setupTestMetrics(t)
+	defer cleanupTestMetricsProducer(t)
+
+	if len(testMetrics) < 2 {
+		t.Skip("need at least two top-level metrics to exercise mid-walk cancellation")
+	}
+
+	ctx, cancel := context.WithCancel(testCtx)
+
+	var visited int
+	err := Walk(ctx, "/", func(ctx context.Context, path string, m Metric, d *Directory) error {
+		if m != nil {
+			visited++
+			// Cancel after the first metric; the per-child ctx.Err()
+			// check in walkDir's loop should stop the walk before a
+			// second metric is visited, rather than running to
+			// completion first.
+			cancel()
+		}
+		return nil
+	})
+
+	if err == nil {
+		t.Fatal("expected Walk to return an error after ctx was cancelled mid-walk")
+	}
+	common.AssertEqual(t, visited, 1, "walk should have stopped after the first metric")
+}
+
+func TestTelemetry_Walk_SkipDir(t *testing.T) {
+	testCtx, _ := setupTestMetrics(t)
+	defer cleanupTestMetricsProducer(t)
+
+	var sawDir bool
+	err := Walk(testCtx, "/", func(ctx context.Context, path string, m Metric, d *Directory) error {
+		if d != nil {
+			sawDir = true
+			return SkipDir
+		}
+		return nil
+	})
+	common.CmpErr(t, nil, err)
+
+	if !sawDir {
+		t.Skip("no subdirectories in test metric tree")
+	}
+}
+
+func TestTelemetry_List(t *testing.T) {
+	testCtx, testMetrics := setupTestMetrics(t)
+	defer cleanupTestMetricsProducer(t)
+
+	children, err := List(testCtx, "/")
+	common.CmpErr(t, nil, err)
+
+	common.AssertEqual(t, len(children), len(testMetrics), "unexpected number of children")
+}